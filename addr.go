@@ -0,0 +1,17 @@
+package reuseport
+
+import "net"
+
+// ResolveAddr resolves the given network and address into a net.Addr
+// suitable for use as a Dialer's LocalAddr. "tcp", "tcp4", "tcp6", "udp",
+// "udp4" and "udp6" are supported.
+func ResolveAddr(network, address string) (net.Addr, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return net.ResolveTCPAddr(network, address)
+	case "udp", "udp4", "udp6":
+		return net.ResolveUDPAddr(network, address)
+	default:
+		return nil, ErrUnsupportedProtocol
+	}
+}
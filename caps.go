@@ -0,0 +1,44 @@
+package reuseport
+
+import "sync"
+
+// Caps describes what this platform's kernel actually does with the
+// reuse sockopts this package sets.
+type Caps struct {
+	// AddressReuse is true if SO_REUSEADDR (or platform equivalent) lets
+	// multiple sockets bind the same local address.
+	AddressReuse bool
+
+	// LoadBalancedReusePort is true if SO_REUSEPORT (or platform
+	// equivalent) additionally load-balances incoming connections/
+	// datagrams across every socket in the group, the way Linux >= 3.9
+	// does. BSD/Darwin implement a SO_REUSEPORT that behaves like
+	// SO_REUSEADDR rather than load-balancing, so this is false there.
+	// Windows has neither, only SO_REUSEADDR/SO_EXCLUSIVEADDRUSE.
+	LoadBalancedReusePort bool
+}
+
+var (
+	capsOnce sync.Once
+	caps     Caps
+)
+
+// Capabilities probes (once, lazily, and then caches the result) what
+// this platform's kernel actually does with SO_REUSEADDR/SO_REUSEPORT,
+// by opening a throwaway socket and attempting each sockopt. Callers can
+// use it to decide whether to fan out one listener per worker or fall
+// back to a single accept loop distributing work themselves.
+func Capabilities() Caps {
+	capsOnce.Do(func() {
+		caps = probeCaps()
+	})
+	return caps
+}
+
+// Available reports whether this platform supports any form of port
+// reuse at all, i.e. Capabilities().AddressReuse ||
+// Capabilities().LoadBalancedReusePort.
+func Available() bool {
+	c := Capabilities()
+	return c.AddressReuse || c.LoadBalancedReusePort
+}
@@ -0,0 +1,22 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package reuseport
+
+import "golang.org/x/sys/unix"
+
+// probeCaps opens a throwaway TCP socket and attempts SO_REUSEADDR on it.
+// SO_REUSEPORT is not probed: BSD/Darwin's SO_REUSEPORT predates Linux's
+// and behaves like SO_REUSEADDR (any-can-bind) rather than
+// load-balancing, so LoadBalancedReusePort is always false here.
+func probeCaps() Caps {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return Caps{}
+	}
+	defer unix.Close(fd)
+
+	var c Caps
+	c.AddressReuse = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1) == nil
+	return c
+}
@@ -0,0 +1,19 @@
+package reuseport
+
+import "golang.org/x/sys/unix"
+
+// probeCaps opens a throwaway TCP socket and attempts SO_REUSEADDR and
+// SO_REUSEPORT on it. Linux >= 3.9 supports both, with SO_REUSEPORT
+// load-balancing connections across the group.
+func probeCaps() Caps {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return Caps{}
+	}
+	defer unix.Close(fd)
+
+	var c Caps
+	c.AddressReuse = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1) == nil
+	c.LoadBalancedReusePort = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEPORT, 1) == nil
+	return c
+}
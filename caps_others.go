@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package reuseport
+
+import "net"
+
+// probeCaps always reports that neither form of reuse is available: on
+// these platforms Control sets no reuse sockopts. It opens and closes a
+// throwaway TCP listener, but the result is Caps{} whether or not that
+// listen succeeds.
+func probeCaps() Caps {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return Caps{}
+	}
+	l.Close()
+	return Caps{}
+}
@@ -0,0 +1,39 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package reuseport
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Control sets SO_REUSEADDR, and SO_REUSEPORT where the platform supports
+// it, on the socket underlying c. It has the exact signature expected by
+// net.ListenConfig.Control and net.Dialer.Control, so callers who need
+// more than this package's Listen/Dial helpers provide (e.g. TLS config,
+// keep-alive tuning, a custom Resolver) can build their own
+// net.ListenConfig{Control: reuseport.Control} or
+// net.Dialer{Control: reuseport.Control} instead of going through us.
+//
+// This is also what listenStream, listenPacket and dial use internally.
+func Control(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = setReuseOpts(int(fd))
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+func setReuseOpts(fd int) error {
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1); err != nil {
+		return err
+	}
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+		return err
+	}
+	return nil
+}
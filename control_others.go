@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package reuseport
+
+import "syscall"
+
+// Control is a no-op on platforms where we don't know how to set
+// SO_REUSEADDR/SO_REUSEPORT via syscall.RawConn. Listen and Dial will
+// still work on these platforms, they just won't have reuse semantics.
+func Control(network, address string, c syscall.RawConn) error {
+	return nil
+}
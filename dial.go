@@ -0,0 +1,25 @@
+package reuseport
+
+import (
+	"context"
+	"net"
+)
+
+// dial is the (background-context) workhorse behind Dial and Dialer.Dial.
+func dial(d net.Dialer, network, address string) (net.Conn, error) {
+	return dialContext(context.Background(), d, network, address)
+}
+
+// dialContext sets d.Control so the dialed socket has SO_REUSEADDR/
+// SO_REUSEPORT set before connect(2), then delegates to net.Dialer.
+func dialContext(ctx context.Context, d net.Dialer, network, address string) (net.Conn, error) {
+	d.Control = Control
+	return d.DialContext(ctx, network, address)
+}
+
+// DialContext dials the given network and address using this Dialer's
+// options, honoring ctx for cancellation and deadlines. See
+// net.Dialer.DialContext.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return dialContext(ctx, d.D, network, address)
+}
@@ -0,0 +1,60 @@
+package reuseport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// reuseErrShouldRetry classifies whether a failed reuse-port dial is
+// worth retrying through a plain net.Dialer (no LocalAddr, OS picks the
+// port). It returns true for the errors we expect when the requested
+// local 4-tuple is already in use: EADDRINUSE, EADDRNOTAVAIL, and
+// connections still lingering in TIME-WAIT.
+func reuseErrShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.EADDRINUSE, syscall.EADDRNOTAVAIL:
+			return true
+		}
+	}
+	return false
+}
+
+// DialFallback dials network/address using d, and if the reuse-port dial
+// fails with an error classified by reuseErrShouldRetry as worth
+// retrying, transparently falls back to a plain net.Dialer with
+// LocalAddr cleared so the OS picks a random ephemeral port. This
+// prefers the reused local port (useful for NAT hole-punching) but
+// degrades gracefully instead of surfacing the underlying errno.
+//
+// If d.Fallback is false, DialFallback does not retry: it reports a
+// retryable reuse failure as ErrReuseFailed instead of the raw errno, so
+// callers can errors.Is against a stable sentinel.
+//
+// The fallback respects ctx: if ctx is already done, or becomes done
+// before the fallback dial starts, DialFallback returns ctx.Err()
+// instead of retrying.
+func (d *Dialer) DialFallback(ctx context.Context, network, address string) (net.Conn, error) {
+	c, err := d.DialContext(ctx, network, address)
+	if err == nil || !reuseErrShouldRetry(err) {
+		return c, err
+	}
+	if !d.Fallback {
+		return nil, ErrReuseFailed
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	fallback := d.D
+	fallback.LocalAddr = nil
+	fallback.Control = nil
+	return fallback.DialContext(ctx, network, address)
+}
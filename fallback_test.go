@@ -0,0 +1,29 @@
+package reuseport
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestReuseErrShouldRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"EADDRINUSE", syscall.EADDRINUSE, true},
+		{"EADDRNOTAVAIL", syscall.EADDRNOTAVAIL, true},
+		{"unrelated errno", syscall.ENOENT, false},
+		{"EADDRINUSE text only, no errno", errors.New("dial tcp: " + syscall.EADDRINUSE.Error()), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reuseErrShouldRetry(tt.err); got != tt.want {
+				t.Errorf("reuseErrShouldRetry(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -18,22 +18,54 @@
 package reuseport
 
 import (
+	"context"
 	"errors"
 	"net"
-	"time"
 )
 
 // ErrUnsuportedProtocol signals that the protocol is not currently
-// supported by this package. This package currently only supports TCP.
+// supported by this package. This package currently supports TCP and
+// UDP ("tcp"/"tcp4"/"tcp6"/"udp"/"udp4"/"udp6").
 var ErrUnsupportedProtocol = errors.New("protocol not yet supported")
 
 // ErrReuseFailed is returned if a reuse attempt was unsuccessful.
 var ErrReuseFailed = errors.New("reuse failed")
 
+// ErrNoLoadBalancedReusePort is returned by Listen when
+// RequireLoadBalancedReusePort() was passed but this platform's kernel
+// only gives address-reuse semantics (or nothing at all) for
+// SO_REUSEPORT. See Capabilities.
+var ErrNoLoadBalancedReusePort = errors.New("platform does not support load-balanced SO_REUSEPORT")
+
+// ListenOption configures Listen. See RequireLoadBalancedReusePort.
+type ListenOption func(*listenOptions)
+
+type listenOptions struct {
+	requireLoadBalance bool
+}
+
+// RequireLoadBalancedReusePort makes Listen fail fast with
+// ErrNoLoadBalancedReusePort instead of succeeding with surprising
+// behavior when the current platform only offers SO_REUSEADDR-like
+// semantics for SO_REUSEPORT (BSD/Darwin), or no reuse at all (Windows
+// and others). Use it when your listener group relies on the kernel
+// load-balancing accepted connections across sockets, e.g. one listener
+// per worker.
+func RequireLoadBalancedReusePort() ListenOption {
+	return func(o *listenOptions) { o.requireLoadBalance = true }
+}
+
 // Listen listens at the given network and address. see net.Listen
 // Returns a net.Listener created from a file discriptor for a socket
 // with SO_REUSEPORT and SO_REUSEADDR option set.
-func Listen(network, address string) (net.Listener, error) {
+func Listen(network, address string, opts ...ListenOption) (net.Listener, error) {
+	var o listenOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.requireLoadBalance && !Capabilities().LoadBalancedReusePort {
+		return nil, ErrNoLoadBalancedReusePort
+	}
 	return listenStream(network, address)
 }
 
@@ -48,7 +80,6 @@ func ListenPacket(network, address string) (net.PacketConn, error) {
 // Returns a net.Conn created from a file discriptor for a socket
 // with SO_REUSEPORT and SO_REUSEADDR option set.
 func Dial(network, laddr, raddr string) (net.Conn, error) {
-
 	var d Dialer
 	if laddr != "" {
 		netladdr, err := ResolveAddr(network, laddr)
@@ -57,30 +88,52 @@ func Dial(network, laddr, raddr string) (net.Conn, error) {
 		}
 		d.D.LocalAddr = netladdr
 	}
+	return d.Dial(network, raddr)
+}
 
-	// there's a rare case where dial returns successfully but for some reason the
-	// RemoteAddr is not yet set. We wait here a while until it is, and if too long
-	// passes, we fail.
-	c, err := dial(d.D, network, raddr)
-	if err != nil {
-		return nil, err
+// ListenContext is like Listen, but takes a context that governs the
+// listen call itself (name resolution, socket setup), the way
+// net.ListenConfig.Listen does. It does not bound Accept calls made on
+// the returned net.Listener.
+func ListenContext(ctx context.Context, network, address string, opts ...ListenOption) (net.Listener, error) {
+	var o listenOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
+	if o.requireLoadBalance && !Capabilities().LoadBalancedReusePort {
+		return nil, ErrNoLoadBalancedReusePort
+	}
+	return listenStreamContext(ctx, network, address)
+}
 
-	for start := time.Now(); c.RemoteAddr() == nil; {
-		if time.Now().Sub(start) > time.Second {
-			c.Close()
-			return nil, ErrReuseFailed
-		}
-
-		<-time.After(20 * time.Microsecond)
+// ListenPacketContext is like ListenPacket, but takes a context that
+// governs the listen call itself, the way net.ListenConfig.ListenPacket
+// does.
+func ListenPacketContext(ctx context.Context, network, address string, opts ...ListenOption) (net.PacketConn, error) {
+	var o listenOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.requireLoadBalance && !Capabilities().LoadBalancedReusePort {
+		return nil, ErrNoLoadBalancedReusePort
 	}
-	return c, nil
+	return listenPacketContext(ctx, network, address)
 }
 
 // Dialer is used to specify the Dial options, much like net.Dialer.
 // We simply wrap a net.Dialer.
 type Dialer struct {
 	D net.Dialer
+
+	// Fallback makes DialFallback retry a failed reuse-port dial through
+	// a plain net.Dialer (OS-assigned port) instead of surfacing the
+	// error. It has no effect on Dial or DialContext.
+	Fallback bool
+
+	// PktInfo makes ListenPacketWithDialer request IP_PKTINFO /
+	// IPV6_RECVPKTINFO ancillary data on the listened socket. It has no
+	// effect on Dial, DialContext or DialFallback.
+	PktInfo bool
 }
 
 // Dial dials the given network and address. see net.Dialer.Dial
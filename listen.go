@@ -0,0 +1,28 @@
+package reuseport
+
+import (
+	"context"
+	"net"
+)
+
+// listenStream is the (background-context) workhorse behind Listen.
+func listenStream(network, address string) (net.Listener, error) {
+	return listenStreamContext(context.Background(), network, address)
+}
+
+// listenPacket is the (background-context) workhorse behind ListenPacket.
+func listenPacket(network, address string) (net.PacketConn, error) {
+	return listenPacketContext(context.Background(), network, address)
+}
+
+// listenStreamContext is the workhorse behind ListenContext.
+func listenStreamContext(ctx context.Context, network, address string) (net.Listener, error) {
+	lc := net.ListenConfig{Control: Control}
+	return lc.Listen(ctx, network, address)
+}
+
+// listenPacketContext is the workhorse behind ListenPacketContext.
+func listenPacketContext(ctx context.Context, network, address string) (net.PacketConn, error) {
+	lc := net.ListenConfig{Control: Control}
+	return lc.ListenPacket(ctx, network, address)
+}
@@ -0,0 +1,29 @@
+package reuseport
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// controlWithPktInfo does everything Control does, and additionally asks
+// the kernel to attach IP_PKTINFO/IPV6_RECVPKTINFO ancillary data to
+// datagrams received on this socket.
+func controlWithPktInfo(network, address string, c syscall.RawConn) error {
+	if err := Control(network, address, c); err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		switch network {
+		case "udp6":
+			sockErr = unix.SetsockoptInt(int(fd), unix.SOL_IPV6, unix.IPV6_RECVPKTINFO, 1)
+		default:
+			sockErr = unix.SetsockoptInt(int(fd), unix.SOL_IP, unix.IP_PKTINFO, 1)
+		}
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package reuseport
+
+import "syscall"
+
+// controlWithPktInfo falls back to plain Control on platforms where we
+// don't know how to request PKTINFO ancillary data.
+func controlWithPktInfo(network, address string, c syscall.RawConn) error {
+	return Control(network, address, c)
+}
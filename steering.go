@@ -0,0 +1,19 @@
+package reuseport
+
+import "errors"
+
+// ErrSteeringUnsupported is returned by ListenWithSteering on kernels
+// that don't implement SO_ATTACH_REUSEPORT_CBPF (pre-4.5 Linux, where it
+// comes wrapped in a *net.OpError, or any non-Linux platform), so
+// callers can fall back to the default (hash-based) SO_REUSEPORT
+// distribution instead.
+var ErrSteeringUnsupported = errors.New("reuseport: SO_ATTACH_REUSEPORT_CBPF not supported on this kernel")
+
+// steeringGroupSize is shared validation for the program builders in
+// steering_linux.go.
+func steeringGroupSize(groupSize int) error {
+	if groupSize <= 0 {
+		return errors.New("reuseport: steering group size must be positive")
+	}
+	return nil
+}
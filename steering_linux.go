@@ -0,0 +1,124 @@
+package reuseport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// CPUIDSteeringProgram compiles a classic-BPF program that routes each
+// incoming SYN/datagram to socket (SKF_AD_CPU % groupSize) in the
+// listener group, i.e. round-robin over N workers pinned one-per-CPU.
+func CPUIDSteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	if err := steeringGroupSize(groupSize); err != nil {
+		return nil, err
+	}
+	return bpf.Assemble([]bpf.Instruction{
+		bpf.LoadExtension{Num: bpf.ExtCPUID},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(groupSize)},
+		bpf.RetA{},
+	})
+}
+
+// SourceHashSteeringProgram compiles a classic-BPF program that hashes
+// the IPv4 source address XORed with the source port, modulo groupSize,
+// so a given remote peer consistently lands on the same socket in the
+// group for the lifetime of the listener set.
+func SourceHashSteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	if err := steeringGroupSize(groupSize); err != nil {
+		return nil, err
+	}
+	return bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 12, Size: 4}, // IPv4 source address
+		bpf.TAX{},
+		bpf.LoadAbsolute{Off: 20, Size: 2}, // source port (no IP options)
+		bpf.ALUOpX{Op: bpf.ALUOpXor},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(groupSize)},
+		bpf.RetA{},
+	})
+}
+
+// QUICStickySteeringProgram compiles a classic-BPF program that hashes
+// the first four bytes of a short-header QUIC connection ID (immediately
+// following the UDP header, itself immediately following a
+// no-options IPv4 header) modulo groupSize, so retransmits and
+// subsequent packets for the same QUIC connection keep landing on the
+// same socket. groupSize is fixed in the compiled program, so resizing
+// the group needs a new program, which maps connections afresh.
+func QUICStickySteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	if err := steeringGroupSize(groupSize); err != nil {
+		return nil, err
+	}
+	return bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 29, Size: 4}, // first 4 bytes of the QUIC CID
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(groupSize)},
+		bpf.RetA{},
+	})
+}
+
+// ListenWithSteering is like Listen, but additionally attaches prog to
+// the listening socket via SO_ATTACH_REUSEPORT_CBPF, so the kernel uses
+// it (rather than its default hash) to pick which socket in a
+// SO_REUSEPORT group handles each incoming SYN or datagram. prog is
+// typically produced by CPUIDSteeringProgram, SourceHashSteeringProgram
+// or QUICStickySteeringProgram.
+//
+// On kernels older than 4.5, which lack SO_ATTACH_REUSEPORT_CBPF,
+// ListenWithSteering returns a *net.OpError wrapping
+// ErrSteeringUnsupported (errors.Is matches it), so callers can fall
+// back to the default SO_REUSEPORT distribution.
+func ListenWithSteering(network, address string, prog []bpf.RawInstruction) (net.Listener, error) {
+	lc := net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			if err := Control(network, address, c); err != nil {
+				return err
+			}
+			return attachSteering(c, prog)
+		},
+	}
+	return lc.Listen(context.Background(), network, address)
+}
+
+// ListenPacketWithSteering is the ListenPacket counterpart of
+// ListenWithSteering, for UDP listener groups.
+func ListenPacketWithSteering(network, address string, prog []bpf.RawInstruction) (net.PacketConn, error) {
+	lc := net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			if err := Control(network, address, c); err != nil {
+				return err
+			}
+			return attachSteering(c, prog)
+		},
+	}
+	return lc.ListenPacket(context.Background(), network, address)
+}
+
+func attachSteering(c syscall.RawConn, prog []bpf.RawInstruction) error {
+	if len(prog) == 0 {
+		return errors.New("reuseport: steering program must not be empty")
+	}
+
+	raw := make([]unix.SockFilter, len(prog))
+	for i, ins := range prog {
+		raw[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	fprog := unix.SockFprog{
+		Len:    uint16(len(raw)),
+		Filter: &raw[0],
+	}
+
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptSockFprog(int(fd), unix.SOL_SOCKET, unix.SO_ATTACH_REUSEPORT_CBPF, &fprog)
+	}); err != nil {
+		return err
+	}
+	if sockErr == unix.ENOPROTOOPT {
+		return ErrSteeringUnsupported
+	}
+	return sockErr
+}
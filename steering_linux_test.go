@@ -0,0 +1,87 @@
+package reuseport
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/bpf"
+)
+
+func TestSteeringProgramsRejectNonPositiveGroupSize(t *testing.T) {
+	builders := []func(int) ([]bpf.RawInstruction, error){
+		CPUIDSteeringProgram,
+		SourceHashSteeringProgram,
+		QUICStickySteeringProgram,
+	}
+	for _, build := range builders {
+		if _, err := build(0); err == nil {
+			t.Errorf("groupSize=0: expected error, got nil")
+		}
+		if _, err := build(-1); err == nil {
+			t.Errorf("groupSize=-1: expected error, got nil")
+		}
+	}
+}
+
+func TestCPUIDSteeringProgram(t *testing.T) {
+	got, err := CPUIDSteeringProgram(4)
+	if err != nil {
+		t.Fatalf("CPUIDSteeringProgram: %v", err)
+	}
+	want, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadExtension{Num: bpf.ExtCPUID},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: 4},
+		bpf.RetA{},
+	})
+	if err != nil {
+		t.Fatalf("assembling expected program: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CPUIDSteeringProgram(4) = %#v, want %#v", got, want)
+	}
+}
+
+func TestSourceHashSteeringProgram(t *testing.T) {
+	got, err := SourceHashSteeringProgram(8)
+	if err != nil {
+		t.Fatalf("SourceHashSteeringProgram: %v", err)
+	}
+	want, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 12, Size: 4},
+		bpf.TAX{},
+		bpf.LoadAbsolute{Off: 20, Size: 2},
+		bpf.ALUOpX{Op: bpf.ALUOpXor},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: 8},
+		bpf.RetA{},
+	})
+	if err != nil {
+		t.Fatalf("assembling expected program: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SourceHashSteeringProgram(8) = %#v, want %#v", got, want)
+	}
+}
+
+func TestQUICStickySteeringProgram(t *testing.T) {
+	got, err := QUICStickySteeringProgram(2)
+	if err != nil {
+		t.Fatalf("QUICStickySteeringProgram: %v", err)
+	}
+	want, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 29, Size: 4},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: 2},
+		bpf.RetA{},
+	})
+	if err != nil {
+		t.Fatalf("assembling expected program: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QUICStickySteeringProgram(2) = %#v, want %#v", got, want)
+	}
+}
+
+func TestAttachSteeringRejectsEmptyProgram(t *testing.T) {
+	if err := attachSteering(nil, nil); err == nil {
+		t.Errorf("attachSteering with empty program: expected error, got nil")
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !linux
+// +build !linux
+
+package reuseport
+
+import (
+	"net"
+
+	"golang.org/x/net/bpf"
+)
+
+// CPUIDSteeringProgram is unsupported outside Linux; it always returns
+// ErrSteeringUnsupported.
+func CPUIDSteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	return nil, ErrSteeringUnsupported
+}
+
+// SourceHashSteeringProgram is unsupported outside Linux; it always
+// returns ErrSteeringUnsupported.
+func SourceHashSteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	return nil, ErrSteeringUnsupported
+}
+
+// QUICStickySteeringProgram is unsupported outside Linux; it always
+// returns ErrSteeringUnsupported.
+func QUICStickySteeringProgram(groupSize int) ([]bpf.RawInstruction, error) {
+	return nil, ErrSteeringUnsupported
+}
+
+// ListenWithSteering is unsupported outside Linux, which is the only
+// platform implementing SO_ATTACH_REUSEPORT_CBPF; it always returns
+// ErrSteeringUnsupported.
+func ListenWithSteering(network, address string, prog []bpf.RawInstruction) (net.Listener, error) {
+	return nil, ErrSteeringUnsupported
+}
+
+// ListenPacketWithSteering is unsupported outside Linux; it always
+// returns ErrSteeringUnsupported.
+func ListenPacketWithSteering(network, address string, prog []bpf.RawInstruction) (net.PacketConn, error) {
+	return nil, ErrSteeringUnsupported
+}
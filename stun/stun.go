@@ -0,0 +1,241 @@
+// Package stun performs just enough of RFC 5389 to reflect the public
+// transport address of a socket back to it: send a Binding Request,
+// parse the XOR-MAPPED-ADDRESS out of the Binding Success Response.
+//
+// It's meant to be paired with sockets returned by the parent reuseport
+// package: because those have SO_REUSEPORT/SO_REUSEADDR set and a stable
+// local port, the address Reflect/ReflectTCP returns stays valid for
+// subsequent hole-punching dials from reuseport.Dial.
+package stun
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+const magicCookie uint32 = 0x2112A442
+
+const (
+	bindingRequest     = 0x0001
+	bindingSuccessResp = 0x0101
+	attrXORMappedAddr  = 0x0020
+	headerLen          = 20
+
+	familyIPv4 = 0x01
+	familyIPv6 = 0x02
+
+	maxBackoff = 8 * time.Second
+)
+
+// ErrNoXORMappedAddress is returned when a STUN response was well formed
+// but carried no XOR-MAPPED-ADDRESS attribute.
+var ErrNoXORMappedAddress = errors.New("stun: response had no XOR-MAPPED-ADDRESS attribute")
+
+// ErrTransactionMismatch is returned when a STUN response's transaction
+// ID didn't match the request it's supposedly answering.
+var ErrTransactionMismatch = errors.New("stun: response transaction ID mismatch")
+
+// ErrNotBindingSuccess is returned when a STUN response wasn't a Binding
+// Success Response (e.g. it was a Binding Error Response).
+var ErrNotBindingSuccess = errors.New("stun: response was not a Binding Success Response")
+
+// Reflect sends a STUN (RFC 5389) Binding Request to server over pc and
+// returns the reflexive transport address the server observed pc's
+// packets arriving from.
+//
+// The request is retransmitted with exponential backoff (500ms, 1s, 2s,
+// ... capped at 8s) per RFC 5389 §7.2.1 until a valid response arrives
+// or ctx is done.
+func Reflect(ctx context.Context, pc net.PacketConn, server string) (net.Addr, error) {
+	raddr, err := net.ResolveUDPAddr("udp", server)
+	if err != nil {
+		return nil, err
+	}
+
+	req, txID, err := newBindingRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	backoff := 500 * time.Millisecond
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if _, err := pc.WriteTo(req, raddr); err != nil {
+			return nil, err
+		}
+
+		readDeadline := time.Now().Add(backoff)
+		if dl, ok := ctx.Deadline(); ok && dl.Before(readDeadline) {
+			readDeadline = dl
+		}
+		pc.SetReadDeadline(readDeadline)
+
+		n, _, err := pc.ReadFrom(buf)
+		if err == nil {
+			if addr, perr := parseBindingResponse(buf[:n], txID); perr == nil {
+				return addr, nil
+			}
+		}
+
+		if backoff < maxBackoff {
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+}
+
+// ReflectTCP is the ReflectTCP variant of Reflect for a TCP conn already
+// dialed to a STUN server (e.g. via reuseport.Dial), for symmetric-NAT
+// probing: since TCP already guarantees delivery, only one Binding
+// Request is sent.
+func ReflectTCP(ctx context.Context, conn net.Conn) (net.Addr, error) {
+	req, txID, err := newBindingRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if dl, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(dl)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[2:4]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+
+	return parseBindingResponse(append(header, body...), txID)
+}
+
+// newBindingRequest builds a 20-byte STUN Binding Request header (no
+// attributes) with a random 96-bit transaction ID, per RFC 5389 §6.
+func newBindingRequest() (msg []byte, txID [12]byte, err error) {
+	if _, err = rand.Read(txID[:]); err != nil {
+		return nil, txID, err
+	}
+
+	msg = make([]byte, headerLen)
+	binary.BigEndian.PutUint16(msg[0:2], bindingRequest)
+	binary.BigEndian.PutUint16(msg[2:4], 0) // message length: no attributes
+	binary.BigEndian.PutUint32(msg[4:8], magicCookie)
+	copy(msg[8:20], txID[:])
+	return msg, txID, nil
+}
+
+// parseBindingResponse validates msg as a Binding Success Response
+// matching txID and extracts its XOR-MAPPED-ADDRESS attribute.
+func parseBindingResponse(msg []byte, txID [12]byte) (net.Addr, error) {
+	if len(msg) < headerLen {
+		return nil, io.ErrUnexpectedEOF
+	}
+	msgType := binary.BigEndian.Uint16(msg[0:2])
+	msgLen := binary.BigEndian.Uint16(msg[2:4])
+	if binary.BigEndian.Uint32(msg[4:8]) != magicCookie {
+		return nil, errors.New("stun: bad magic cookie")
+	}
+	if !bytesEqual(msg[8:20], txID[:]) {
+		return nil, ErrTransactionMismatch
+	}
+	if msgType != bindingSuccessResp {
+		return nil, ErrNotBindingSuccess
+	}
+	if int(headerLen)+int(msgLen) > len(msg) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	attrs := msg[headerLen : headerLen+int(msgLen)]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		val := attrs[4 : 4+attrLen]
+
+		if attrType == attrXORMappedAddr {
+			return parseXORMappedAddress(val, txID)
+		}
+
+		// attributes are padded to a 4-byte boundary
+		advance := 4 + attrLen
+		if pad := attrLen % 4; pad != 0 {
+			advance += 4 - pad
+		}
+		if advance > len(attrs) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		attrs = attrs[advance:]
+	}
+	return nil, ErrNoXORMappedAddress
+}
+
+// parseXORMappedAddress decodes an XOR-MAPPED-ADDRESS attribute body per
+// RFC 5389 §15.2: the port is XORed with the high 16 bits of the magic
+// cookie, and the address is XORed with the magic cookie (IPv4) or the
+// magic cookie followed by the transaction ID (IPv6).
+func parseXORMappedAddress(val []byte, txID [12]byte) (net.Addr, error) {
+	if len(val) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	family := val[1]
+	xport := binary.BigEndian.Uint16(val[2:4])
+	port := int(xport ^ uint16(magicCookie>>16))
+
+	var cookieAndTxID [16]byte
+	binary.BigEndian.PutUint32(cookieAndTxID[0:4], magicCookie)
+	copy(cookieAndTxID[4:16], txID[:])
+
+	switch family {
+	case familyIPv4:
+		if len(val) < 8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		ip := make(net.IP, 4)
+		for i := 0; i < 4; i++ {
+			ip[i] = val[4+i] ^ cookieAndTxID[i]
+		}
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	case familyIPv6:
+		if len(val) < 20 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		ip := make(net.IP, 16)
+		for i := 0; i < 16; i++ {
+			ip[i] = val[4+i] ^ cookieAndTxID[i]
+		}
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	default:
+		return nil, errors.New("stun: unknown address family")
+	}
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package stun
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func mustTxID(t *testing.T, b byte) [12]byte {
+	t.Helper()
+	var txID [12]byte
+	for i := range txID {
+		txID[i] = b
+	}
+	return txID
+}
+
+// buildXORMappedAddress builds a padded XOR-MAPPED-ADDRESS attribute TLV
+// for ip/port, XOR-encoded against magicCookie/txID per RFC 5389 §15.2.
+func buildXORMappedAddress(ip net.IP, port int, txID [12]byte) []byte {
+	var cookieAndTxID [16]byte
+	binary.BigEndian.PutUint32(cookieAndTxID[0:4], magicCookie)
+	copy(cookieAndTxID[4:16], txID[:])
+
+	var family byte
+	var addr []byte
+	if ip4 := ip.To4(); ip4 != nil {
+		family = familyIPv4
+		addr = make([]byte, 4)
+		for i := range addr {
+			addr[i] = ip4[i] ^ cookieAndTxID[i]
+		}
+	} else {
+		family = familyIPv6
+		ip16 := ip.To16()
+		addr = make([]byte, 16)
+		for i := range addr {
+			addr[i] = ip16[i] ^ cookieAndTxID[i]
+		}
+	}
+
+	val := make([]byte, 4+len(addr))
+	val[1] = family
+	binary.BigEndian.PutUint16(val[2:4], uint16(port)^uint16(magicCookie>>16))
+	copy(val[4:], addr)
+
+	return attrTLV(attrXORMappedAddr, val)
+}
+
+// attrTLV wraps val in a type-length-value attribute, padded to a
+// 4-byte boundary as RFC 5389 §15 requires.
+func attrTLV(attrType uint16, val []byte) []byte {
+	tlv := make([]byte, 4+len(val))
+	binary.BigEndian.PutUint16(tlv[0:2], attrType)
+	binary.BigEndian.PutUint16(tlv[2:4], uint16(len(val)))
+	copy(tlv[4:], val)
+	if pad := len(val) % 4; pad != 0 {
+		tlv = append(tlv, make([]byte, 4-pad)...)
+	}
+	return tlv
+}
+
+// buildResponse assembles a full STUN message: header + attrs.
+func buildResponse(msgType uint16, cookie uint32, txID [12]byte, attrs []byte) []byte {
+	msg := make([]byte, headerLen+len(attrs))
+	binary.BigEndian.PutUint16(msg[0:2], msgType)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(attrs)))
+	binary.BigEndian.PutUint32(msg[4:8], cookie)
+	copy(msg[8:20], txID[:])
+	copy(msg[20:], attrs)
+	return msg
+}
+
+func TestParseBindingResponseIPv4(t *testing.T) {
+	txID := mustTxID(t, 0x42)
+	wantIP := net.ParseIP("203.0.113.5").To4()
+	wantPort := 54321
+
+	attrs := buildXORMappedAddress(wantIP, wantPort, txID)
+	msg := buildResponse(bindingSuccessResp, magicCookie, txID, attrs)
+
+	addr, err := parseBindingResponse(msg, txID)
+	if err != nil {
+		t.Fatalf("parseBindingResponse: %v", err)
+	}
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		t.Fatalf("addr = %#v, want *net.UDPAddr", addr)
+	}
+	if !udpAddr.IP.Equal(wantIP) || udpAddr.Port != wantPort {
+		t.Errorf("got %s:%d, want %s:%d", udpAddr.IP, udpAddr.Port, wantIP, wantPort)
+	}
+}
+
+func TestParseBindingResponseIPv6(t *testing.T) {
+	txID := mustTxID(t, 0x7)
+	wantIP := net.ParseIP("2001:db8::1")
+	wantPort := 4242
+
+	attrs := buildXORMappedAddress(wantIP, wantPort, txID)
+	msg := buildResponse(bindingSuccessResp, magicCookie, txID, attrs)
+
+	addr, err := parseBindingResponse(msg, txID)
+	if err != nil {
+		t.Fatalf("parseBindingResponse: %v", err)
+	}
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		t.Fatalf("addr = %#v, want *net.UDPAddr", addr)
+	}
+	if !udpAddr.IP.Equal(wantIP) || udpAddr.Port != wantPort {
+		t.Errorf("got %s:%d, want %s:%d", udpAddr.IP, udpAddr.Port, wantIP, wantPort)
+	}
+}
+
+func TestParseBindingResponseTransactionMismatch(t *testing.T) {
+	txID := mustTxID(t, 0x1)
+	otherTxID := mustTxID(t, 0x2)
+	attrs := buildXORMappedAddress(net.ParseIP("192.0.2.1"), 1, txID)
+	msg := buildResponse(bindingSuccessResp, magicCookie, txID, attrs)
+
+	if _, err := parseBindingResponse(msg, otherTxID); err != ErrTransactionMismatch {
+		t.Errorf("err = %v, want ErrTransactionMismatch", err)
+	}
+}
+
+func TestParseBindingResponseNotSuccess(t *testing.T) {
+	txID := mustTxID(t, 0x1)
+	attrs := buildXORMappedAddress(net.ParseIP("192.0.2.1"), 1, txID)
+	msg := buildResponse(0x0111, magicCookie, txID, attrs) // Binding Error Response
+
+	if _, err := parseBindingResponse(msg, txID); err != ErrNotBindingSuccess {
+		t.Errorf("err = %v, want ErrNotBindingSuccess", err)
+	}
+}
+
+// TestParseBindingResponseTruncatedAttribute reproduces a response whose
+// last attribute claims a length that isn't a multiple of 4 and carries
+// no padding bytes, so the naive "attrs[advance:]" used to slice past
+// the end of the buffer and panic.
+func TestParseBindingResponseTruncatedAttribute(t *testing.T) {
+	txID := mustTxID(t, 0x9)
+
+	// A bogus, non-XOR-MAPPED-ADDRESS attribute with attrLen=1 and a
+	// single value byte -- no padding present, unlike a real TLV.
+	bogus := make([]byte, 5)
+	binary.BigEndian.PutUint16(bogus[0:2], 0x9999)
+	binary.BigEndian.PutUint16(bogus[2:4], 1)
+	bogus[4] = 0xAB
+
+	msg := buildResponse(bindingSuccessResp, magicCookie, txID, bogus)
+
+	addr, err := parseBindingResponse(msg, txID)
+	if err == nil {
+		t.Fatalf("expected an error for the truncated attribute, got addr=%v", addr)
+	}
+}
+
+func TestParseXORMappedAddressTooShort(t *testing.T) {
+	txID := mustTxID(t, 0x1)
+	if _, err := parseXORMappedAddress([]byte{0, 0}, txID); err == nil {
+		t.Errorf("expected an error for a too-short attribute body")
+	}
+}
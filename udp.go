@@ -0,0 +1,31 @@
+package reuseport
+
+import (
+	"context"
+	"net"
+)
+
+// ListenPacketWithDialer is like ListenPacket, but takes a Dialer so its
+// PktInfo option can be honored. Set d.PktInfo to have the kernel attach
+// IP_PKTINFO (IPv4) or IPV6_RECVPKTINFO (IPv6) ancillary data to
+// received datagrams, so a UDP listener sharing a port with other
+// reuseport listeners (e.g. several processes on "0.0.0.0:1234") can
+// tell which local address a given datagram arrived on and reply from
+// the same one.
+//
+// network must be "udp", "udp4" or "udp6".
+func ListenPacketWithDialer(d *Dialer, network, address string) (net.PacketConn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+	default:
+		return nil, ErrUnsupportedProtocol
+	}
+
+	control := Control
+	if d.PktInfo {
+		control = controlWithPktInfo
+	}
+
+	lc := net.ListenConfig{Control: control}
+	return lc.ListenPacket(context.Background(), network, address)
+}